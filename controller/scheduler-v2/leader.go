@@ -0,0 +1,123 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	discoverd "github.com/flynn/flynn/discoverd/client"
+)
+
+// leaderLeaseTTL is the discoverd TTL backing the scheduler's leader lease.
+// The elector renews it well before expiry so a live process never loses
+// leadership to its own timeout.
+const leaderLeaseTTL = 10 * time.Second
+
+// LeaderElector decides which of a set of Scheduler processes is allowed to
+// mutate cluster state. It is backed by a discoverd service using a
+// TTL-based lease: only the instance holding the lease is the leader, and
+// every other instance stays hot, ready to take over the moment the lease
+// changes hands.
+type LeaderElector interface {
+	// Leader reports whether this process currently holds the lease.
+	Leader() bool
+	// Leaders streams leadership transitions as they happen.
+	Leaders() <-chan bool
+	Close() error
+}
+
+// discoverdElector implements LeaderElector on top of a discoverd service,
+// registering self as an instance and electing whichever instance
+// discoverd reports as the service leader.
+type discoverdElector struct {
+	service discoverd.Service
+	self    *discoverd.Instance
+
+	mtx     sync.Mutex
+	leading bool
+
+	leaders  chan bool
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewDiscoverdElector registers self with service and elects a leader from
+// its instances, renewing self's lease every leaderLeaseTTL/2 so the
+// registration doesn't expire out from under a live process.
+func NewDiscoverdElector(service discoverd.Service, self *discoverd.Instance) (LeaderElector, error) {
+	if err := service.Register(self); err != nil {
+		return nil, err
+	}
+	e := &discoverdElector{
+		service: service,
+		self:    self,
+		leaders: make(chan bool, 1),
+		stop:    make(chan struct{}),
+	}
+	go e.run(service.Leaders())
+	return e, nil
+}
+
+func (e *discoverdElector) run(leaders <-chan *discoverd.Instance) {
+	ticker := time.NewTicker(leaderLeaseTTL / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.stop:
+			return
+		case leader, ok := <-leaders:
+			if !ok {
+				// discoverd connection lost or the service was torn down:
+				// we can no longer tell who holds the lease, so assume we
+				// don't rather than risk a partitioned former-leader
+				// scheduling forever.
+				e.setLeading(false)
+				return
+			}
+			e.setLeading(leader != nil && leader.ID == e.self.ID)
+		case <-ticker.C:
+			// renew the TTL lease so discoverd doesn't expire us while
+			// we're still alive and leading; if renewal fails we may already
+			// be losing the lease, so demote immediately rather than wait
+			// for a stale Leaders() update
+			if err := e.service.Register(e.self); err != nil {
+				e.setLeading(false)
+			}
+		}
+	}
+}
+
+func (e *discoverdElector) setLeading(leading bool) {
+	e.mtx.Lock()
+	changed := leading != e.leading
+	e.leading = leading
+	e.mtx.Unlock()
+	if !changed {
+		return
+	}
+	// Leaders() only ever needs the most recent transition, so drop a
+	// stale pending value rather than block the election goroutine.
+	select {
+	case e.leaders <- leading:
+	default:
+		select {
+		case <-e.leaders:
+		default:
+		}
+		e.leaders <- leading
+	}
+}
+
+func (e *discoverdElector) Leader() bool {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	return e.leading
+}
+
+func (e *discoverdElector) Leaders() <-chan bool {
+	return e.leaders
+}
+
+func (e *discoverdElector) Close() error {
+	e.stopOnce.Do(func() { close(e.stop) })
+	return e.service.Unregister(e.self)
+}
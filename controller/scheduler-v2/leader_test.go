@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/flynn/flynn/Godeps/_workspace/src/github.com/flynn/go-check"
+	discoverd "github.com/flynn/flynn/discoverd/client"
+)
+
+// fakeDiscoverdService implements just enough of discoverd.Service for
+// discoverdElector: embedding the interface lets it satisfy the rest without
+// ever being called, since discoverdElector only uses Register, Unregister
+// and Leaders.
+type fakeDiscoverdService struct {
+	discoverd.Service
+
+	leaders     chan *discoverd.Instance
+	registerErr error
+
+	registerCount int
+	unregistered  bool
+}
+
+func newFakeDiscoverdService() *fakeDiscoverdService {
+	return &fakeDiscoverdService{leaders: make(chan *discoverd.Instance, 1)}
+}
+
+func (f *fakeDiscoverdService) Register(inst *discoverd.Instance) error {
+	f.registerCount++
+	return f.registerErr
+}
+
+func (f *fakeDiscoverdService) Unregister(inst *discoverd.Instance) error {
+	f.unregistered = true
+	return nil
+}
+
+func (f *fakeDiscoverdService) Leaders() <-chan *discoverd.Instance {
+	return f.leaders
+}
+
+func waitForLeader(e LeaderElector, want bool) error {
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if e.Leader() == want {
+			return nil
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return errors.New("timed out waiting for leadership state")
+}
+
+func (ts *TestSuite) TestDiscoverdElectorTracksLeadershipTransitions(c *C) {
+	self := &discoverd.Instance{ID: "self"}
+	other := &discoverd.Instance{ID: "other"}
+	svc := newFakeDiscoverdService()
+
+	e, err := NewDiscoverdElector(svc, self)
+	c.Assert(err, IsNil)
+	defer e.Close()
+
+	c.Assert(svc.registerCount, Equals, 1)
+	c.Assert(e.Leader(), Equals, false)
+
+	svc.leaders <- self
+	c.Assert(waitForLeader(e, true), IsNil)
+
+	svc.leaders <- other
+	c.Assert(waitForLeader(e, false), IsNil)
+}
+
+// TestDiscoverdElectorDemotesWhenLeadersCloses simulates the discoverd
+// connection dropping out from under a leading instance: it must self-demote
+// rather than keep reporting Leader() == true forever.
+func (ts *TestSuite) TestDiscoverdElectorDemotesWhenLeadersCloses(c *C) {
+	self := &discoverd.Instance{ID: "self"}
+	svc := newFakeDiscoverdService()
+
+	e, err := NewDiscoverdElector(svc, self)
+	c.Assert(err, IsNil)
+	defer e.Close()
+
+	svc.leaders <- self
+	c.Assert(waitForLeader(e, true), IsNil)
+
+	close(svc.leaders)
+	c.Assert(waitForLeader(e, false), IsNil)
+}
+
+func (ts *TestSuite) TestDiscoverdElectorCloseUnregisters(c *C) {
+	self := &discoverd.Instance{ID: "self"}
+	svc := newFakeDiscoverdService()
+
+	e, err := NewDiscoverdElector(svc, self)
+	c.Assert(err, IsNil)
+
+	c.Assert(e.Close(), IsNil)
+	c.Assert(svc.unregistered, Equals, true)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/flynn/flynn/controller/utils"
+)
+
+// errNoHostFits is returned by findBestHost when no host satisfies a job's
+// resource requirements or tag constraints.
+var errNoHostFits = errors.New("no host satisfies job constraints")
+
+// pendingRetryInterval is how long the scheduler waits before re-evaluating
+// a job request that couldn't be placed, giving capacity a chance to free
+// up or a new host to join.
+const pendingRetryInterval = 5 * time.Second
+
+// Resources describes the CPU and memory a job asks for, sourced from the
+// release's process type config.
+type Resources struct {
+	CPUShares   int64
+	MemoryBytes int64
+}
+
+// Placer scores candidate hosts for a job request, letting a formation
+// choose between strategies such as spreading replicas across hosts or
+// bin-packing onto as few hosts as possible. Score is higher-is-better;
+// findBestHost has already filtered out hosts that can't fit the request
+// or don't match its required tags.
+type Placer interface {
+	Score(host utils.HostClient, capacity utils.HostCapacity, req Resources, typ string, counts map[string]int) float64
+}
+
+// SpreadPlacer is the default strategy: it favors hosts with the most free
+// capacity and the fewest existing jobs of the requested type, spreading
+// replicas across the cluster for anti-affinity.
+type SpreadPlacer struct{}
+
+func (SpreadPlacer) Score(host utils.HostClient, capacity utils.HostCapacity, req Resources, typ string, counts map[string]int) float64 {
+	free := freeRatio(capacity, req)
+	spread := 1 / float64(counts[host.ID()]+1)
+	return free*0.5 + spread*0.5
+}
+
+// BinPackPlacer favors the most-utilized host that can still fit the
+// request, keeping other hosts empty for as long as possible so they can
+// be reclaimed.
+type BinPackPlacer struct{}
+
+func (BinPackPlacer) Score(host utils.HostClient, capacity utils.HostCapacity, req Resources, typ string, counts map[string]int) float64 {
+	return 1 - freeRatio(capacity, req)
+}
+
+// freeRatio is the smaller of the CPU and memory fractions that would
+// remain free on a host after placing req, so neither dimension alone can
+// make a nearly-full host look attractive.
+func freeRatio(capacity utils.HostCapacity, req Resources) float64 {
+	cpuRatio, memRatio := 1.0, 1.0
+	if capacity.CPUShares > 0 {
+		cpuRatio = float64(capacity.CPUShares-req.CPUShares) / float64(capacity.CPUShares)
+	}
+	if capacity.MemoryBytes > 0 {
+		memRatio = float64(capacity.MemoryBytes-req.MemoryBytes) / float64(capacity.MemoryBytes)
+	}
+	return math.Min(cpuRatio, memRatio)
+}
+
+// fits reports whether a host has enough free capacity for req.
+func fits(capacity utils.HostCapacity, req Resources) bool {
+	return capacity.CPUShares >= req.CPUShares && capacity.MemoryBytes >= req.MemoryBytes
+}
+
+// tagsMatch reports whether a host's tags satisfy all of a job's required
+// tags, e.g. required["region"] == "us-east-1".
+func tagsMatch(hostTags, required map[string]string) bool {
+	for k, v := range required {
+		if hostTags[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// retryPending re-queues req after pendingRetryInterval, giving a job that
+// couldn't be placed another chance once capacity or hosts change.
+func (s *Scheduler) retryPending(req *JobRequest) {
+	time.AfterFunc(pendingRetryInterval, func() {
+		select {
+		case s.jobRequests <- req:
+		case <-s.stop:
+		}
+	})
+}
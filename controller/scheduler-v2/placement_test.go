@@ -0,0 +1,131 @@
+package main
+
+import (
+	. "github.com/flynn/flynn/Godeps/_workspace/src/github.com/flynn/go-check"
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/controller/utils"
+)
+
+func (ts *TestSuite) TestFits(c *C) {
+	capacity := utils.HostCapacity{CPUShares: 100, MemoryBytes: 1000}
+	c.Assert(fits(capacity, Resources{CPUShares: 100, MemoryBytes: 1000}), Equals, true)
+	c.Assert(fits(capacity, Resources{CPUShares: 101, MemoryBytes: 0}), Equals, false)
+	c.Assert(fits(capacity, Resources{CPUShares: 0, MemoryBytes: 1001}), Equals, false)
+}
+
+func (ts *TestSuite) TestTagsMatch(c *C) {
+	hostTags := map[string]string{"zone": "us-east-1", "disk": "ssd"}
+	c.Assert(tagsMatch(hostTags, nil), Equals, true)
+	c.Assert(tagsMatch(hostTags, map[string]string{"zone": "us-east-1"}), Equals, true)
+	c.Assert(tagsMatch(hostTags, map[string]string{"zone": "us-west-2"}), Equals, false)
+	c.Assert(tagsMatch(hostTags, map[string]string{"gpu": "true"}), Equals, false)
+}
+
+func (ts *TestSuite) TestFreeRatioPicksTighterDimension(c *C) {
+	// 90% of CPU free but only 10% of memory free: the smaller ratio wins
+	capacity := utils.HostCapacity{CPUShares: 100, MemoryBytes: 1000}
+	ratio := freeRatio(capacity, Resources{CPUShares: 10, MemoryBytes: 900})
+	c.Assert(ratio > 0.05 && ratio < 0.15, Equals, true)
+}
+
+func (ts *TestSuite) TestSpreadPlacerFavorsLessLoadedHost(c *C) {
+	quiet := NewFakeHostClient("quiet-host")
+	busy := NewFakeHostClient("busy-host")
+	capacity := utils.HostCapacity{CPUShares: 100, MemoryBytes: 1000}
+	req := Resources{CPUShares: 10, MemoryBytes: 100}
+	counts := map[string]int{busy.ID(): 5}
+
+	placer := SpreadPlacer{}
+	quietScore := placer.Score(quiet, capacity, req, testJobType, counts)
+	busyScore := placer.Score(busy, capacity, req, testJobType, counts)
+	c.Assert(quietScore > busyScore, Equals, true)
+}
+
+func (ts *TestSuite) TestBinPackPlacerFavorsMoreUtilizedHost(c *C) {
+	h := NewFakeHostClient(testHostID)
+	req := Resources{CPUShares: 10, MemoryBytes: 100}
+	counts := map[string]int{}
+
+	placer := BinPackPlacer{}
+	full := placer.Score(h, utils.HostCapacity{CPUShares: 20, MemoryBytes: 200}, req, testJobType, counts)
+	empty := placer.Score(h, utils.HostCapacity{CPUShares: 1000, MemoryBytes: 10000}, req, testJobType, counts)
+	c.Assert(full > empty, Equals, true)
+}
+
+// TestFindBestHostRejectsTagMismatch requires a tag no real host will ever
+// coincidentally have, so findBestHost must filter out every host and
+// report errNoHostFits regardless of what capacity or tags the fake host
+// defaults to.
+func (ts *TestSuite) TestFindBestHostRejectsTagMismatch(c *C) {
+	s := createTestScheduler()
+
+	release, err := s.GetRelease(testReleaseID)
+	c.Assert(err, IsNil)
+	release.Processes[testJobType] = ct.ProcessType{
+		Tags: map[string]string{"zone": "impossible-zone-xyz"},
+	}
+
+	form := s.formations.Get(testAppID, testReleaseID)
+	if form == nil {
+		app, err := s.GetApp(testAppID)
+		c.Assert(err, IsNil)
+		artifact, err := s.GetArtifact(release.ArtifactID)
+		c.Assert(err, IsNil)
+		form = NewFormation(&ct.ExpandedFormation{
+			App:       app,
+			Release:   release,
+			Artifact:  artifact,
+			Processes: map[string]int{testJobType: testJobCount},
+		})
+	}
+
+	req := NewJobRequest(form, JobRequestTypeUp, testJobType, "", "")
+	_, err = s.findBestHost(req)
+	c.Assert(err, Equals, errNoHostFits)
+}
+
+// TestStartJobEmitsPendingEventWhenNoHostFits checks the EventTypeJobPending
+// path added alongside findBestHost's filtering: a request that can't be
+// placed anywhere must surface EventTypeJobPending (not just an error)
+// before startJob schedules a retry.
+func (ts *TestSuite) TestStartJobEmitsPendingEventWhenNoHostFits(c *C) {
+	s := createTestScheduler()
+
+	release, err := s.GetRelease(testReleaseID)
+	c.Assert(err, IsNil)
+	release.Processes[testJobType] = ct.ProcessType{
+		Tags: map[string]string{"zone": "impossible-zone-xyz"},
+	}
+	app, err := s.GetApp(testAppID)
+	c.Assert(err, IsNil)
+	artifact, err := s.GetArtifact(release.ArtifactID)
+	c.Assert(err, IsNil)
+	form := NewFormation(&ct.ExpandedFormation{
+		App:       app,
+		Release:   release,
+		Artifact:  artifact,
+		Processes: map[string]int{testJobType: testJobCount},
+	})
+
+	events := make(chan Event, eventBufferSize)
+	stream := s.Subscribe(events, WithEventTypes(EventTypeJobPending, EventTypeJobStart))
+	defer stream.Close()
+
+	req := NewJobRequest(form, JobRequestTypeUp, testJobType, "", "")
+	err = s.startJob(req)
+	c.Assert(err, Equals, errNoHostFits)
+
+	e, err := waitForEvent(events, EventTypeJobPending)
+	c.Assert(err, IsNil)
+	pending, ok := e.(*JobPendingEvent)
+	c.Assert(ok, Equals, true)
+	c.Assert(pending.Request.Type, Equals, testJobType)
+
+	// errNoHostFits is self-healing via the retry queued above, so it must
+	// not also surface as an errored EventTypeJobStart
+	select {
+	case e := <-events:
+		c.Fatalf("unexpected event after pending: %#v", e)
+	default:
+	}
+}
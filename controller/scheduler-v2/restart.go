@@ -0,0 +1,158 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	backoffBase   = time.Second
+	backoffFactor = 2.0
+	backoffMax    = 5 * time.Minute
+	backoffJitter = 0.2
+
+	// stableWindow is how long a job must stay Running before its backoff
+	// state is reset, so a job that crashes once after a long healthy run
+	// isn't punished with the delay it would have earned from a crash loop.
+	stableWindow = 60 * time.Second
+)
+
+// RestartPolicy controls whether the scheduler restarts a job of a given
+// type after it stops, sourced from the release's process type config.
+type RestartPolicy string
+
+const (
+	RestartPolicyAlways    RestartPolicy = "always"
+	RestartPolicyOnFailure RestartPolicy = "on-failure"
+	RestartPolicyNever     RestartPolicy = "never"
+)
+
+// backoffKey identifies the process type within a formation whose restart
+// history is being tracked. Jobs in this scheduler are identified by their
+// host-assigned ID rather than a stable replica index, so failures are
+// tracked per (app, release, type) rather than per individual slot.
+type backoffKey struct {
+	appID     string
+	releaseID string
+	typ       string
+}
+
+// restartState is the consecutive-failure bookkeeping for one backoffKey.
+type restartState struct {
+	failures  int
+	restartAt time.Time
+}
+
+// backoffTracker computes and stores per-backoffKey restart state, using
+// exponential backoff capped at backoffMax with jitter to avoid thundering
+// herds of simultaneous restarts.
+type backoffTracker struct {
+	mtx    sync.Mutex
+	states map[backoffKey]*restartState
+}
+
+func newBackoffTracker() *backoffTracker {
+	return &backoffTracker{states: make(map[backoffKey]*restartState)}
+}
+
+// Fail records a failure for key and returns the updated consecutive
+// failure count and the delay to wait before restarting.
+func (t *backoffTracker) Fail(key backoffKey) (failures int, delay time.Duration) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	s, ok := t.states[key]
+	if !ok {
+		s = &restartState{}
+		t.states[key] = s
+	}
+	s.failures++
+	delay = backoffDelay(s.failures)
+	s.restartAt = time.Now().Add(delay)
+	return s.failures, delay
+}
+
+// Reset clears the failure count for key, e.g. once a job has been running
+// long enough to be considered stable again.
+func (t *backoffTracker) Reset(key backoffKey) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	delete(t.states, key)
+}
+
+// backoffDelay is base * factor^(failures-1), capped at backoffMax, with a
+// uniform random jitter of +/- backoffJitter applied.
+func backoffDelay(failures int) time.Duration {
+	d := float64(backoffBase) * math.Pow(backoffFactor, float64(failures-1))
+	if d > float64(backoffMax) {
+		d = float64(backoffMax)
+	}
+	d += d * backoffJitter * (rand.Float64()*2 - 1)
+	if d < 0 {
+		d = 0
+	}
+	return time.Duration(d)
+}
+
+// handleJobCrash is called when a job's host reports it exited in error. It
+// looks up the job's restart policy, computes the next backoff delay, and
+// re-issues a JobRequestTypeUp after that delay rather than immediately,
+// so a crash-looping job doesn't restart as fast as the scheduler loop
+// runs.
+func (s *Scheduler) handleJobCrash(e *hostJobEvent) {
+	log := s.log.New("fn", "handleJobCrash", "host.id", e.hostID, "job.id", e.event.JobID)
+
+	job, ok := s.jobs[e.event.JobID]
+	if !ok {
+		log.Info("crash reported for unknown job, ignoring")
+		return
+	}
+	// the job is gone the moment the host reports it errored; remove it now
+	// rather than waiting on the next SyncJobs so RectifyJobs doesn't see a
+	// satisfied formation and skip scheduling a replacement once the
+	// backoff below expires
+	s.RemoveJob(job.JobID)
+
+	policy := job.Formation.Release.Processes[job.Type].Restart
+	if policy == RestartPolicyNever {
+		log.Info("restart policy is never, not restarting")
+		return
+	}
+
+	key := backoffKey{appID: job.AppID, releaseID: job.ReleaseID, typ: job.Type}
+	failures, delay := s.backoff.Fail(key)
+	restartAt := time.Now().Add(delay)
+	log.Info("scheduling restart after backoff", "failures", failures, "delay", delay)
+	s.sendEvent(NewEvent(EventTypeJobCrash, nil, &JobCrash{Job: job, Failures: failures, RestartAt: restartAt}))
+
+	formation, typ := job.Formation, job.Type
+	time.AfterFunc(delay, func() {
+		select {
+		case s.jobRequests <- NewJobRequest(formation, JobRequestTypeUp, typ, "", ""):
+		case <-s.stop:
+		}
+	})
+}
+
+// watchJobStable waits stableWindow and then asks the scheduler loop to
+// reset job's backoff state if it's still running, via s.stableJob so the
+// actual map lookup stays on the single goroutine that owns s.jobs.
+func (s *Scheduler) watchJobStable(job *Job) {
+	select {
+	case <-time.After(stableWindow):
+	case <-s.stop:
+		return
+	}
+	select {
+	case s.stableJob <- job:
+	case <-s.stop:
+	}
+}
+
+// JobCrash is the payload of an EventTypeJobCrash event.
+type JobCrash struct {
+	Job       *Job
+	Failures  int
+	RestartAt time.Time
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	. "github.com/flynn/flynn/Godeps/_workspace/src/github.com/flynn/go-check"
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/host/types"
+)
+
+func (ts *TestSuite) TestBackoffDelayIncreasesMonotonically(c *C) {
+	var prevMax time.Duration
+	for failures := 1; failures <= 10; failures++ {
+		// the jittered delay can dip below the previous failure count's
+		// jittered delay, so check each delay against the upper bound of its
+		// jitter range and check that those bounds never decrease
+		max := time.Duration(float64(backoffBase) * math.Pow(backoffFactor, float64(failures-1)) * (1 + backoffJitter))
+		if max > backoffMax {
+			max = time.Duration(float64(backoffMax) * (1 + backoffJitter))
+		}
+		c.Assert(backoffDelay(failures) <= max, Equals, true)
+		c.Assert(max >= prevMax, Equals, true)
+		prevMax = max
+	}
+}
+
+func (ts *TestSuite) TestBackoffDelayCapped(c *C) {
+	delay := backoffDelay(100)
+	c.Assert(delay <= time.Duration(float64(backoffMax)*(1+backoffJitter)), Equals, true)
+}
+
+// TestHandleJobCrashIncreasesBackoffAndReschedules drives a simulated crash
+// of the same process type through handleJobCrash three times in a row (as
+// a crash-looping job would) and checks the real restart path end-to-end:
+// the crashed job is removed immediately, the reported failure count climbs
+// each time, the reported delay is monotonically increasing, and a restart
+// request for the process type is actually enqueued once that delay fires.
+func (ts *TestSuite) TestHandleJobCrashIncreasesBackoffAndReschedules(c *C) {
+	s := createTestScheduler()
+
+	events := make(chan Event, eventBufferSize)
+	stream := s.Subscribe(events, WithEventTypes(EventTypeJobCrash))
+	defer stream.Close()
+
+	app, err := s.GetApp(testAppID)
+	c.Assert(err, IsNil)
+	release, err := s.GetRelease(testReleaseID)
+	c.Assert(err, IsNil)
+	artifact, err := s.GetArtifact(release.ArtifactID)
+	c.Assert(err, IsNil)
+	form := NewFormation(&ct.ExpandedFormation{
+		App:       app,
+		Release:   release,
+		Artifact:  artifact,
+		Processes: map[string]int{testJobType: testJobCount},
+	})
+
+	var prevDelay time.Duration
+	for i := 0; i < 3; i++ {
+		jobID := fmt.Sprintf("crash-job-%d", i)
+		s.jobs[jobID] = &Job{
+			JobID:     jobID,
+			AppID:     app.ID,
+			ReleaseID: release.ID,
+			HostID:    testHostID,
+			Type:      testJobType,
+			Formation: form,
+		}
+
+		s.handleJobCrash(&hostJobEvent{hostID: testHostID, event: &host.Event{JobID: jobID, Event: host.JobEventError}})
+
+		_, stillPresent := s.jobs[jobID]
+		c.Assert(stillPresent, Equals, false)
+
+		e, err := waitForEvent(events, EventTypeJobCrash)
+		c.Assert(err, IsNil)
+		crash, ok := e.(*JobCrashEvent)
+		c.Assert(ok, Equals, true)
+		c.Assert(crash.Failures, Equals, i+1)
+
+		delay := crash.RestartAt.Sub(time.Now())
+		c.Assert(delay > prevDelay, Equals, true)
+		prevDelay = delay
+	}
+
+	// each crash's restart lands back on jobRequests once its delay fires;
+	// the first to arrive is the earliest crash's, well within the timeout
+	select {
+	case req := <-s.jobRequests:
+		c.Assert(req.RequestType, Equals, JobRequestTypeUp)
+		c.Assert(req.Type, Equals, testJobType)
+	case <-time.After(prevDelay + 2*time.Second):
+		c.Fatal("timed out waiting for crash-triggered restart request")
+	}
+}
+
+func (ts *TestSuite) TestBackoffTrackerResetClearsFailures(c *C) {
+	t := newBackoffTracker()
+	key := backoffKey{appID: testAppID, releaseID: testReleaseID, typ: testJobType}
+
+	failures, _ := t.Fail(key)
+	c.Assert(failures, Equals, 1)
+	failures, _ = t.Fail(key)
+	c.Assert(failures, Equals, 2)
+
+	t.Reset(key)
+	failures, _ = t.Fail(key)
+	c.Assert(failures, Equals, 1)
+}
@@ -24,9 +24,13 @@ type Scheduler struct {
 
 	jobs map[string]*Job
 
-	listeners map[chan Event]struct{}
+	listeners map[chan Event]*Subscription
 	listenMtx sync.RWMutex
 
+	leaderElector LeaderElector
+	isLeader      bool
+	leaderChange  chan bool
+
 	stop     chan struct{}
 	stopOnce sync.Once
 
@@ -37,6 +41,14 @@ type Scheduler struct {
 	formationChange chan *ct.ExpandedFormation
 	jobRequests     chan *JobRequest
 
+	hostEvent       chan *hostJobEvent
+	formationEvent  chan *ct.Formation
+	watchedHosts    map[string]struct{}
+	watchedHostsMtx sync.Mutex
+
+	backoff   *backoffTracker
+	stableJob chan *Job
+
 	validJobStatuses map[host.JobStatus]bool
 }
 
@@ -46,14 +58,20 @@ func NewScheduler(cluster utils.ClusterClient, cc utils.ControllerClient) *Sched
 		ClusterClient:    cluster,
 		log:              log15.New("component", "scheduler"),
 		jobs:             make(map[string]*Job),
-		listeners:        make(map[chan Event]struct{}),
+		listeners:        make(map[chan Event]*Subscription),
 		formations:       make(Formations),
+		leaderChange:     make(chan bool, 1),
 		stop:             make(chan struct{}),
 		jobSync:          make(chan struct{}, eventBufferSize),
 		formationSync:    make(chan struct{}, eventBufferSize),
 		rectifyJobs:      make(chan struct{}, eventBufferSize),
 		formationChange:  make(chan *ct.ExpandedFormation, eventBufferSize),
 		jobRequests:      make(chan *JobRequest, eventBufferSize),
+		hostEvent:        make(chan *hostJobEvent, eventBufferSize),
+		formationEvent:   make(chan *ct.Formation, eventBufferSize),
+		watchedHosts:     make(map[string]struct{}),
+		backoff:          newBackoffTracker(),
+		stableJob:        make(chan *Job, eventBufferSize),
 		validJobStatuses: map[host.JobStatus]bool{
 			host.StatusStarting: true,
 			host.StatusRunning:  true,
@@ -65,11 +83,80 @@ func main() {
 	return
 }
 
+// SetLeaderElector wires a LeaderElector into the scheduler so that
+// leadership transitions reported by it are reflected via ChangeLeader.
+// It must be called before Run.
+func (s *Scheduler) SetLeaderElector(e LeaderElector) {
+	s.leaderElector = e
+}
+
+// ChangeLeader requests a leadership transition. It may be called from any
+// goroutine: the transition itself is only ever applied on the Run
+// goroutine (via changeLeader), which is also the only goroutine that
+// reads s.isLeader, so there's no need to guard it with a mutex.
+func (s *Scheduler) ChangeLeader(leader bool) {
+	select {
+	case s.leaderChange <- leader:
+	case <-s.stop:
+	}
+}
+
+// changeLeader applies a leadership transition. Only the leader is allowed
+// to issue AddJob/StopJob requests against hosts; followers keep their
+// formations/jobs view in sync via SyncJobs and SyncFormations but leave
+// job requests, rectify runs and the job-request-issuing half of formation
+// changes unhandled. On losing leadership, any in-flight job requests are
+// drained so they aren't replayed against stale state if this process is
+// re-elected. It must only be called from the Run goroutine.
+func (s *Scheduler) changeLeader(leader bool) {
+	if leader == s.isLeader {
+		return
+	}
+	s.isLeader = leader
+	if leader {
+		s.log.Info("became leader", "fn", "changeLeader")
+		s.sendEvent(NewEvent(EventTypeLeader, nil, nil))
+		// re-evaluate formations/jobs immediately rather than waiting for
+		// the next tick now that we're in charge of scheduling
+		s.rectifyJobs <- struct{}{}
+	} else {
+		s.log.Info("became follower", "fn", "changeLeader")
+		drainJobRequests(s.jobRequests)
+		s.sendEvent(NewEvent(EventTypeFollower, nil, nil))
+	}
+}
+
 func (s *Scheduler) Run() error {
 	log := s.log.New("fn", "Run")
 	log.Info("starting scheduler loop")
 	defer log.Info("exiting scheduler loop")
 
+	if s.leaderElector != nil {
+		go func() {
+			leaders := s.leaderElector.Leaders()
+			for {
+				select {
+				case leading, ok := <-leaders:
+					if !ok {
+						return
+					}
+					select {
+					case s.leaderChange <- leading:
+					case <-s.stop:
+						return
+					}
+				case <-s.stop:
+					return
+				}
+			}
+		}()
+	}
+
+	go s.watchController()
+
+	// the tickers are now just a slow safety net: host and controller
+	// watches drive almost all scheduling, but a missed/dropped event
+	// shouldn't be able to wedge the scheduler forever
 	jobTicker := time.Tick(30 * time.Second)
 	formationTicker := time.Tick(time.Minute)
 	go func() {
@@ -92,15 +179,25 @@ func (s *Scheduler) Run() error {
 		default:
 		}
 		select {
+		case leading := <-s.leaderChange:
+			s.changeLeader(leading)
+			continue
+		default:
+		}
+		select {
 		case req := <-s.jobRequests:
-			s.HandleJobRequest(req)
+			if s.isLeader {
+				s.HandleJobRequest(req)
+			}
 			continue
 		default:
 		}
 		select {
 		case <-s.rectifyJobs:
-			if err := s.RectifyJobs(); err != nil {
-				log.Error("error rectifying jobs", "err", err)
+			if s.isLeader {
+				if err := s.RectifyJobs(); err != nil {
+					log.Error("error rectifying jobs", "err", err)
+				}
 			}
 			continue
 		default:
@@ -115,6 +212,28 @@ func (s *Scheduler) Run() error {
 		default:
 		}
 		select {
+		case e := <-s.hostEvent:
+			s.handleHostEvent(e)
+			continue
+		default:
+		}
+		select {
+		case f := <-s.formationEvent:
+			if err := s.handleFormationEvent(f); err != nil {
+				log.Error("error handling formation event", "err", err)
+			}
+			continue
+		default:
+		}
+		select {
+		case j := <-s.stableJob:
+			if _, ok := s.jobs[j.JobID]; ok {
+				s.backoff.Reset(backoffKey{appID: j.AppID, releaseID: j.ReleaseID, typ: j.Type})
+			}
+			continue
+		default:
+		}
+		select {
 		case <-s.formationSync:
 			log.Info("starting formation sync")
 			s.SyncFormations()
@@ -151,6 +270,7 @@ func (s *Scheduler) SyncJobs() (err error) {
 		return err
 	}
 	log.Info(fmt.Sprintf("got %d hosts", len(hosts)))
+	s.watchNewHosts(hosts)
 
 	inactiveJobs := make(map[string]*Job)
 	for k, v := range s.jobs {
@@ -336,6 +456,13 @@ func (s *Scheduler) updateFormation(controllerFormation *ct.Formation, appName s
 	return nil
 }
 
+// FormationChange updates the scheduler's view of a formation and, if this
+// process is the leader, issues job requests to converge on it. The
+// bookkeeping runs unconditionally on both leader and follower: it's the
+// only code path that writes to s.formations, so a follower that skipped it
+// would never populate its formations view and would have to wait out a
+// full SyncFormations after an election to catch up. Only the job requests
+// that actually mutate cluster state are gated on leadership.
 func (s *Scheduler) FormationChange(ef *ct.ExpandedFormation) (err error) {
 	log := s.log.New("fn", "FormationChange")
 
@@ -355,6 +482,9 @@ func (s *Scheduler) FormationChange(ef *ct.ExpandedFormation) (err error) {
 	} else {
 		diff = f.Update(ef.Processes)
 	}
+	if !s.isLeader {
+		return nil
+	}
 	for typ, n := range diff {
 		if n > 0 {
 			for i := 0; i < n; i++ {
@@ -394,11 +524,22 @@ func (s *Scheduler) startJob(req *JobRequest) (err error) {
 		if err != nil {
 			log.Error("error starting job", "err", err)
 		}
-		s.sendEvent(NewEvent(EventTypeJobStart, err, job))
+		// errNoHostFits already surfaced as EventTypeJobPending below, which
+		// is the whole signal a consumer needs for this self-healing,
+		// automatically-retried condition; EventTypeJobStart's error case is
+		// reserved for requests that won't resolve on their own.
+		if err != errNoHostFits {
+			s.sendEvent(NewEvent(EventTypeJobStart, err, job))
+		}
 	}()
 
-	host, err := s.findBestHost(req.Type, req.HostID)
+	host, err := s.findBestHost(req)
 	if err != nil {
+		if err == errNoHostFits {
+			log.Info("no host satisfies constraints, will retry", "job.type", req.Type)
+			s.sendEvent(NewEvent(EventTypeJobPending, nil, req))
+			s.retryPending(req)
+		}
 		return err
 	}
 
@@ -423,6 +564,7 @@ func (s *Scheduler) startJob(req *JobRequest) (err error) {
 		return err
 	}
 	log.Info("started job", "host.id", job.HostID, "job.type", job.Type, "job.id", job.JobID)
+	go s.watchJobStable(job)
 	return err
 }
 
@@ -450,7 +592,16 @@ func jobConfig(req *JobRequest, hostID string) *host.Job {
 	return utils.JobConfig(req.Job.Formation.ExpandedFormation, req.Type, hostID)
 }
 
-func (s *Scheduler) findBestHost(typ, hostID string) (utils.HostClient, error) {
+// findBestHost picks a host for req, preferring req.HostID if one was
+// requested explicitly. Otherwise it filters out hosts that can't fit the
+// job's resources or don't match its required tags, then scores the
+// remainder with the formation's Placer (SpreadPlacer by default) and
+// returns the highest-scoring host. If no host fits, it returns
+// errNoHostFits so the caller can surface EventTypeJobPending and retry
+// once capacity changes.
+func (s *Scheduler) findBestHost(req *JobRequest) (utils.HostClient, error) {
+	log := s.log.New("fn", "findBestHost")
+
 	hosts, err := s.Hosts()
 	if err != nil {
 		return nil, err
@@ -459,18 +610,40 @@ func (s *Scheduler) findBestHost(typ, hostID string) (utils.HostClient, error) {
 		return nil, errors.New("no hosts found")
 	}
 
-	if hostID == "" {
-		counts := s.hostJobCounts(typ)
-		var minCount int = math.MaxInt32
-		for _, host := range hosts {
-			count := counts[host.ID()]
-			if count < minCount {
-				minCount = count
-				hostID = host.ID()
-			}
+	if req.HostID != "" {
+		return s.Host(req.HostID)
+	}
+
+	pt := req.Job.Formation.Release.Processes[req.Type]
+	resources := Resources{CPUShares: pt.Resources.CPUShares, MemoryBytes: pt.Resources.MemoryBytes}
+	placer := req.Job.Formation.Placer
+	if placer == nil {
+		placer = SpreadPlacer{}
+	}
+	counts := s.hostJobCounts(req.Type)
+
+	var best utils.HostClient
+	bestScore := math.Inf(-1)
+	for _, h := range hosts {
+		capacity, err := h.Capacity()
+		if err != nil {
+			log.Error("error getting host capacity", "host.id", h.ID(), "err", err)
+			continue
+		}
+		if !fits(capacity, resources) {
+			continue
+		}
+		if !tagsMatch(h.Tags(), pt.Tags) {
+			continue
+		}
+		if score := placer.Score(h, capacity, resources, req.Type, counts); best == nil || score > bestScore {
+			best, bestScore = h, score
 		}
 	}
-	return s.Host(hostID)
+	if best == nil {
+		return nil, errNoHostFits
+	}
+	return best, nil
 }
 
 func (s *Scheduler) hostJobCounts(typ string) map[string]int {
@@ -487,22 +660,40 @@ func (s *Scheduler) hostJobCounts(typ string) map[string]int {
 func (s *Scheduler) Stop() error {
 	s.log.Info("stopping scheduler loop", "fn", "Stop")
 	s.stopOnce.Do(func() { close(s.stop) })
+	if s.leaderElector != nil {
+		return s.leaderElector.Close()
+	}
 	return nil
 }
 
-func (s *Scheduler) Subscribe(events chan Event) *Stream {
+// Subscribe registers events to receive a copy of every scheduler Event.
+// By default the subscription buffers up to eventBufferSize events and
+// drops the oldest on overflow; pass WithBufferSize, WithOverflowPolicy or
+// WithEventTypes to change that. Delivery happens on a dedicated goroutine
+// per subscription, so a slow or stuck subscriber can never stall
+// sendEvent.
+func (s *Scheduler) Subscribe(events chan Event, opts ...SubscribeOption) *Stream {
 	s.log.Info("adding subscriber", "fn", "Subscribe")
+	o := subscribeOptions{bufferSize: eventBufferSize, policy: DropOldest}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	sub := newSubscription(events, o)
 	s.listenMtx.Lock()
-	defer s.listenMtx.Unlock()
-	s.listeners[events] = struct{}{}
-	return &Stream{s, events}
+	s.listeners[events] = sub
+	s.listenMtx.Unlock()
+	return &Stream{s, events, sub}
 }
 
 func (s *Scheduler) Unsubscribe(events chan Event) {
 	s.log.Info("removing subscriber", "fn", "Unsubscribe")
 	s.listenMtx.Lock()
-	defer s.listenMtx.Unlock()
+	sub, ok := s.listeners[events]
 	delete(s.listeners, events)
+	s.listenMtx.Unlock()
+	if ok {
+		sub.Close()
+	}
 }
 
 func (s *Scheduler) AddJob(job *Job, appName string, metadata map[string]string) (*Job, error) {
@@ -538,9 +729,20 @@ func drainChannel(ch chan struct{}) {
 	}
 }
 
+func drainJobRequests(ch chan *JobRequest) {
+	for {
+		select {
+		case <-ch:
+		default:
+			return
+		}
+	}
+}
+
 type Stream struct {
 	s      *Scheduler
 	events chan Event
+	sub    *Subscription
 }
 
 func (s *Stream) Close() error {
@@ -548,13 +750,24 @@ func (s *Stream) Close() error {
 	return nil
 }
 
+// DroppedEvents returns how many events this stream's subscription has
+// discarded under DropOldest.
+func (s *Stream) DroppedEvents() int64 {
+	return s.sub.DroppedEvents()
+}
+
+// Err returns ErrSubscriberOverflow if this stream was torn down because
+// its CloseOnOverflow subscription overflowed, nil otherwise.
+func (s *Stream) Err() error {
+	return s.sub.Err()
+}
+
 func (s *Scheduler) sendEvent(event Event) {
 	s.listenMtx.RLock()
 	defer s.listenMtx.RUnlock()
 	s.log.Info("sending event to listeners", "event.type", event.Type(), "listeners.count", len(s.listeners))
-	for ch := range s.listeners {
-		// TODO: handle slow listeners
-		ch <- event
+	for _, sub := range s.listeners {
+		sub.Send(event)
 	}
 }
 
@@ -573,6 +786,10 @@ const (
 	EventTypeRectifyJobs     EventType = "rectify-jobs"
 	EventTypeJobStart        EventType = "start-job"
 	EventTypeJobStop         EventType = "stop-job"
+	EventTypeLeader          EventType = "leader"
+	EventTypeFollower        EventType = "follower"
+	EventTypeJobCrash        EventType = "job-crash"
+	EventTypeJobPending      EventType = "job-pending"
 )
 
 type DefaultEvent struct {
@@ -593,6 +810,16 @@ type JobStartEvent struct {
 	Job *Job
 }
 
+type JobCrashEvent struct {
+	Event
+	*JobCrash
+}
+
+type JobPendingEvent struct {
+	Event
+	Request *JobRequest
+}
+
 func NewEvent(typ EventType, err error, data interface{}) Event {
 	switch typ {
 	case EventTypeJobStart:
@@ -601,6 +828,18 @@ func NewEvent(typ EventType, err error, data interface{}) Event {
 			job = nil
 		}
 		return &JobStartEvent{Event: &DefaultEvent{err: err, typ: typ}, Job: job}
+	case EventTypeJobCrash:
+		crash, ok := data.(*JobCrash)
+		if !ok {
+			crash = nil
+		}
+		return &JobCrashEvent{Event: &DefaultEvent{err: err, typ: typ}, JobCrash: crash}
+	case EventTypeJobPending:
+		req, ok := data.(*JobRequest)
+		if !ok {
+			req = nil
+		}
+		return &JobPendingEvent{Event: &DefaultEvent{err: err, typ: typ}, Request: req}
 	default:
 		return &DefaultEvent{err: err, typ: typ}
 	}
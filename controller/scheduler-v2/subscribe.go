@@ -0,0 +1,186 @@
+package main
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrSubscriberOverflow is the reason a CloseOnOverflow subscription was
+// torn down: its buffer filled up faster than the subscriber could drain
+// it. Retrieve it from Stream.Err after the subscriber channel stops
+// receiving events.
+var ErrSubscriberOverflow = errors.New("scheduler: subscriber overflowed its event buffer")
+
+// OverflowPolicy decides what a Subscription does when its buffer is full
+// and another event arrives.
+type OverflowPolicy int
+
+const (
+	// DropOldest discards the oldest buffered event to make room and
+	// increments DroppedEvents. This is the default.
+	DropOldest OverflowPolicy = iota
+	// CloseOnOverflow tears down the subscription instead of dropping
+	// events, so a subscriber that requires a gap-free stream finds out
+	// rather than silently missing events.
+	CloseOnOverflow
+)
+
+// SubscribeOption configures a single Subscribe call.
+type SubscribeOption func(*subscribeOptions)
+
+type subscribeOptions struct {
+	bufferSize int
+	policy     OverflowPolicy
+	types      map[EventType]struct{}
+}
+
+// WithBufferSize sets how many events a subscription buffers before
+// applying its OverflowPolicy. Defaults to eventBufferSize.
+func WithBufferSize(n int) SubscribeOption {
+	return func(o *subscribeOptions) { o.bufferSize = n }
+}
+
+// WithOverflowPolicy sets what happens when the buffer fills up. Defaults
+// to DropOldest.
+func WithOverflowPolicy(p OverflowPolicy) SubscribeOption {
+	return func(o *subscribeOptions) { o.policy = p }
+}
+
+// WithEventTypes restricts delivery to the given event types; with none
+// given (the default), every event type is delivered.
+func WithEventTypes(types ...EventType) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.types = make(map[EventType]struct{}, len(types))
+		for _, t := range types {
+			o.types[t] = struct{}{}
+		}
+	}
+}
+
+// Subscription owns a bounded buffer of events for a single subscriber and
+// a goroutine that drains it into the subscriber's channel, so a slow
+// subscriber blocks only itself and never the scheduler's sendEvent path.
+type Subscription struct {
+	out    chan Event
+	types  map[EventType]struct{}
+	policy OverflowPolicy
+	cap    int
+
+	mtx         sync.Mutex
+	buf         []Event
+	dropped     int64
+	overflowErr error
+
+	notify    chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newSubscription(out chan Event, opts subscribeOptions) *Subscription {
+	sub := &Subscription{
+		out:    out,
+		types:  opts.types,
+		policy: opts.policy,
+		cap:    opts.bufferSize,
+		notify: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+	go sub.drain()
+	return sub
+}
+
+// Send enqueues event for delivery. It never blocks: it takes the
+// subscription's own lock just long enough to buffer the event (or apply
+// the overflow policy), then returns.
+func (s *Subscription) Send(event Event) {
+	if s.isClosed() {
+		return
+	}
+	if len(s.types) > 0 {
+		if _, ok := s.types[event.Type()]; !ok {
+			return
+		}
+	}
+
+	s.mtx.Lock()
+	if len(s.buf) >= s.cap {
+		if s.policy == CloseOnOverflow {
+			s.mtx.Unlock()
+			s.overflow()
+			return
+		}
+		s.buf = s.buf[1:]
+		s.dropped++
+	}
+	s.buf = append(s.buf, event)
+	s.mtx.Unlock()
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Subscription) overflow() {
+	s.mtx.Lock()
+	s.overflowErr = ErrSubscriberOverflow
+	s.mtx.Unlock()
+	s.Close()
+}
+
+func (s *Subscription) isClosed() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *Subscription) drain() {
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.notify:
+		}
+		for {
+			s.mtx.Lock()
+			if len(s.buf) == 0 {
+				s.mtx.Unlock()
+				break
+			}
+			event := s.buf[0]
+			s.buf = s.buf[1:]
+			s.mtx.Unlock()
+
+			select {
+			case s.out <- event:
+			case <-s.done:
+				return
+			}
+		}
+	}
+}
+
+// Close stops the subscription's drain goroutine. It's safe to call
+// concurrently with in-flight Sends and safe to call more than once.
+func (s *Subscription) Close() {
+	s.closeOnce.Do(func() { close(s.done) })
+}
+
+// DroppedEvents returns how many events this subscription has discarded
+// under DropOldest.
+func (s *Subscription) DroppedEvents() int64 {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.dropped
+}
+
+// Err returns ErrSubscriberOverflow if a CloseOnOverflow subscription was
+// torn down due to overflow, nil otherwise.
+func (s *Subscription) Err() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.overflowErr
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	. "github.com/flynn/flynn/Godeps/_workspace/src/github.com/flynn/go-check"
+	ct "github.com/flynn/flynn/controller/types"
+)
+
+// TestSlowSubscriberDoesNotBlockScheduler registers a subscriber that never
+// reads from its channel and asserts the scheduler keeps delivering events
+// to a well-behaved subscriber and keeps processing job requests anyway.
+func (ts *TestSuite) TestSlowSubscriberDoesNotBlockScheduler(c *C) {
+	blocked := make(chan Event) // deliberately never drained
+	normal := make(chan Event, eventBufferSize)
+
+	sched := runTestScheduler(normal, true)
+	defer sched.Stop()
+	s := sched.scheduler
+
+	blockedStream := s.Subscribe(blocked, WithBufferSize(1))
+	defer blockedStream.Close()
+
+	_, err := waitForEvent(normal, EventTypeRectifyJobs)
+	c.Assert(err, IsNil)
+	_, err = waitForEvent(normal, EventTypeJobStart)
+	c.Assert(err, IsNil)
+
+	app, err := s.GetApp(testAppID)
+	c.Assert(err, IsNil)
+	release, err := s.GetRelease(testReleaseID)
+	c.Assert(err, IsNil)
+
+	s.PutFormation(&ct.Formation{AppID: app.ID, ReleaseID: release.ID, Processes: map[string]int{testJobType: 2}})
+	_, err = waitForEvent(normal, EventTypeFormationChange)
+	c.Assert(err, IsNil)
+	_, err = waitForEvent(normal, EventTypeJobStart)
+	c.Assert(err, IsNil)
+
+	c.Assert(blockedStream.DroppedEvents() > 0, Equals, true)
+}
+
+func (ts *TestSuite) TestCloseOnOverflowTearsDownSubscription(c *C) {
+	events := make(chan Event) // deliberately never drained
+	sched := runTestScheduler(make(chan Event, eventBufferSize), true)
+	defer sched.Stop()
+	s := sched.scheduler
+
+	stream := s.Subscribe(events, WithBufferSize(1), WithOverflowPolicy(CloseOnOverflow))
+	defer stream.Close()
+
+	// the drain goroutine can dequeue at most one event before it wedges
+	// trying to deliver it to the never-read channel, so a handful of sends
+	// is always enough to overflow a buffer of size 1
+	for i := 0; i < 10; i++ {
+		s.sendEvent(NewEvent(EventTypeDefault, nil, nil))
+	}
+
+	c.Assert(stream.Err(), Equals, ErrSubscriberOverflow)
+}
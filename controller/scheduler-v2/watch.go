@@ -0,0 +1,155 @@
+package main
+
+import (
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/controller/utils"
+	"github.com/flynn/flynn/host/types"
+)
+
+// hostJobEvent pairs a host.Event with the host it came from, so
+// handleHostEvent doesn't have to thread hostID through the channel
+// separately.
+type hostJobEvent struct {
+	hostID string
+	event  *host.Event
+}
+
+// watchHost streams host.Events for hostID into s.hostEvent so that job
+// crashes and stops are rectified within milliseconds instead of waiting
+// for the next jobSync tick. It returns once the host's event stream ends,
+// which includes the host going away, treated the same as a job loss.
+func (s *Scheduler) watchHost(hostID string) {
+	log := s.log.New("fn", "watchHost", "host.id", hostID)
+	events, err := s.HostEvents(hostID)
+	if err != nil {
+		log.Error("error subscribing to host events", "err", err)
+		return
+	}
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				log.Info("host event stream closed, treating as host down")
+				s.hostEvent <- &hostJobEvent{hostID: hostID, event: nil}
+				return
+			}
+			s.hostEvent <- &hostJobEvent{hostID: hostID, event: event}
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// watchNewHosts starts a watchHost goroutine for any host in hosts that
+// isn't already being watched. It is safe to call repeatedly, e.g. after
+// every SyncJobs, as new hosts join the cluster.
+func (s *Scheduler) watchNewHosts(hosts []utils.HostClient) {
+	s.watchedHostsMtx.Lock()
+	defer s.watchedHostsMtx.Unlock()
+	for _, h := range hosts {
+		if _, ok := s.watchedHosts[h.ID()]; ok {
+			continue
+		}
+		s.watchedHosts[h.ID()] = struct{}{}
+		go s.watchHost(h.ID())
+	}
+}
+
+// watchController streams controller formation and app events into
+// s.formationEvent, replacing the minute-ly SyncFormations tick as the
+// primary way formation edits reach the scheduler. The tick stays in
+// place, but SyncFormations only lists formations while the scheduler has
+// none, so it does not pick up an edit whose event was missed.
+func (s *Scheduler) watchController() {
+	log := s.log.New("fn", "watchController")
+	formations, err := s.FormationEvents()
+	if err != nil {
+		log.Error("error subscribing to formation events", "err", err)
+		return
+	}
+	apps, err := s.AppEvents()
+	if err != nil {
+		log.Error("error subscribing to app events", "err", err)
+		return
+	}
+	for {
+		select {
+		case f, ok := <-formations:
+			if !ok {
+				return
+			}
+			s.formationEvent <- f
+		case _, ok := <-apps:
+			if !ok {
+				return
+			}
+			// app create/delete doesn't itself change process counts; queue
+			// a formation sync, which lists every app's formations only
+			// while the scheduler has none
+			s.formationSync <- struct{}{}
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// handleHostEvent translates a single host.Event into a targeted rectify
+// or, for a job that exited in error, a backoff-scheduled restart rather
+// than a global SyncJobs. A nil event is the sentinel watchHost sends when
+// the host's event stream closes, meaning the host itself is down.
+func (s *Scheduler) handleHostEvent(e *hostJobEvent) {
+	if e.event == nil {
+		s.handleHostDown(e.hostID)
+		return
+	}
+	log := s.log.New("fn", "handleHostEvent", "host.id", e.hostID, "job.id", e.event.JobID, "event.type", e.event.Event)
+	switch e.event.Event {
+	case host.JobEventStop:
+		log.Info("rectifying after job stop")
+		// drop the stopped job first so RectifyJobs sees the formation as
+		// short of it and schedules a replacement
+		s.RemoveJob(e.event.JobID)
+		s.rectifyJobs <- struct{}{}
+	case host.JobEventError:
+		s.handleJobCrash(e)
+	default:
+		log.Info("ignoring host event")
+	}
+}
+
+// handleHostDown drops every job the scheduler thought was running on
+// hostID and stops treating the host as watched, so a host ID that
+// reappears later (e.g. the same host rejoining after a restart) gets a
+// fresh watchHost goroutine instead of being silently ignored by
+// watchNewHosts. It must only be called from the Run goroutine, since it
+// mutates s.jobs.
+func (s *Scheduler) handleHostDown(hostID string) {
+	log := s.log.New("fn", "handleHostDown", "host.id", hostID)
+	for id, job := range s.jobs {
+		if job.HostID == hostID {
+			log.Info("removing job on down host", "job.id", id)
+			s.RemoveJob(id)
+		}
+	}
+
+	s.watchedHostsMtx.Lock()
+	delete(s.watchedHosts, hostID)
+	s.watchedHostsMtx.Unlock()
+
+	log.Info("rectifying after host down")
+	s.rectifyJobs <- struct{}{}
+}
+
+// handleFormationEvent looks up the owning app and feeds the formation
+// through the same path as a SyncFormations-discovered change, queuing a
+// formationChange that FormationChange then diffs against the scheduler's
+// view.
+func (s *Scheduler) handleFormationEvent(f *ct.Formation) error {
+	log := s.log.New("fn", "handleFormationEvent", "app.id", f.AppID, "release.id", f.ReleaseID)
+	app, err := s.GetApp(f.AppID)
+	if err != nil {
+		log.Error("error getting app", "err", err)
+		return err
+	}
+	return s.updateFormation(f, app.Name)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	. "github.com/flynn/flynn/Godeps/_workspace/src/github.com/flynn/go-check"
+	ct "github.com/flynn/flynn/controller/types"
+)
+
+// TestHandleHostDownRemovesJobsAndUnwatchesHost simulates watchHost's "host
+// event stream closed" path and checks the two things it must do so the
+// scheduler doesn't wait out a slow SyncJobs to notice a host is gone:
+// remove every job the scheduler thought was running on that host, and stop
+// treating the host as watched so a host ID that later rejoins gets a fresh
+// watchHost goroutine instead of being ignored by watchNewHosts.
+func (ts *TestSuite) TestHandleHostDownRemovesJobsAndUnwatchesHost(c *C) {
+	s := createTestScheduler()
+
+	app, err := s.GetApp(testAppID)
+	c.Assert(err, IsNil)
+	release, err := s.GetRelease(testReleaseID)
+	c.Assert(err, IsNil)
+	artifact, err := s.GetArtifact(release.ArtifactID)
+	c.Assert(err, IsNil)
+	form := NewFormation(&ct.ExpandedFormation{
+		App:       app,
+		Release:   release,
+		Artifact:  artifact,
+		Processes: map[string]int{testJobType: testJobCount},
+	})
+
+	s.jobs["job-on-down-host"] = &Job{
+		JobID:     "job-on-down-host",
+		AppID:     app.ID,
+		ReleaseID: release.ID,
+		HostID:    testHostID,
+		Type:      testJobType,
+		Formation: form,
+	}
+	s.jobs["job-on-other-host"] = &Job{
+		JobID:     "job-on-other-host",
+		AppID:     app.ID,
+		ReleaseID: release.ID,
+		HostID:    "some-other-host",
+		Type:      testJobType,
+		Formation: form,
+	}
+	s.watchedHosts[testHostID] = struct{}{}
+
+	s.handleHostEvent(&hostJobEvent{hostID: testHostID, event: nil})
+
+	_, stillThere := s.jobs["job-on-down-host"]
+	c.Assert(stillThere, Equals, false)
+	_, otherStillThere := s.jobs["job-on-other-host"]
+	c.Assert(otherStillThere, Equals, true)
+
+	s.watchedHostsMtx.Lock()
+	_, watched := s.watchedHosts[testHostID]
+	s.watchedHostsMtx.Unlock()
+	c.Assert(watched, Equals, false)
+
+	select {
+	case <-s.rectifyJobs:
+	default:
+		c.Fatal("expected a rectifyJobs signal after host down")
+	}
+}